@@ -0,0 +1,118 @@
+package handler
+
+import "sync"
+
+const (
+	// defaultDisableThreshold is the number of consecutive failures (provider
+	// update errors, or resolver errors other than errEmptyResult /
+	// errEmptyDomain) at which a domain is marked disabled, used when
+	// settings.Settings.DisableThreshold isn't configured.
+	defaultDisableThreshold = 10
+	// maxBackoffTicks caps how many ticks a failing domain is skipped for,
+	// even as its failure count keeps growing.
+	maxBackoffTicks = 16
+)
+
+// domainFailureState tracks consecutive failures for a single domain so
+// LoopUpdateIP can back off a misbehaving provider or resolver instead of
+// retrying it every tick forever.
+type domainFailureState struct {
+	consecutiveFailures int
+	skipTicks           int
+	disabled            bool
+}
+
+// failureTracker is Handler's per-domain backoff/auto-disable bookkeeping.
+// It is safe for concurrent use since each domain's worker goroutine only
+// ever touches its own key, but a mutex is kept to guard the map itself.
+type failureTracker struct {
+	mu               sync.Mutex
+	states           map[string]*domainFailureState
+	disableThreshold int
+}
+
+// newFailureTracker creates a failureTracker that disables a domain after
+// disableThreshold consecutive failures. A non-positive disableThreshold
+// falls back to defaultDisableThreshold.
+func newFailureTracker(disableThreshold int) *failureTracker {
+	if disableThreshold <= 0 {
+		disableThreshold = defaultDisableThreshold
+	}
+	return &failureTracker{
+		states:           make(map[string]*domainFailureState),
+		disableThreshold: disableThreshold,
+	}
+}
+
+func (t *failureTracker) stateFor(domainKey string) *domainFailureState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.states[domainKey]
+	if !ok {
+		state = &domainFailureState{}
+		t.states[domainKey] = state
+	}
+	return state
+}
+
+// shouldSkip reports whether domainKey is currently disabled or serving out
+// a backoff period, consuming one tick of backoff if so.
+func (t *failureTracker) shouldSkip(domainKey string) bool {
+	state := t.stateFor(domainKey)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if state.disabled {
+		return true
+	}
+	if state.skipTicks > 0 {
+		state.skipTicks--
+		return true
+	}
+	return false
+}
+
+// recordSuccess clears domainKey's failure count and backoff.
+func (t *failureTracker) recordSuccess(domainKey string) {
+	state := t.stateFor(domainKey)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state.consecutiveFailures = 0
+	state.skipTicks = 0
+}
+
+// recordFailure records a failure for domainKey, applies exponential
+// backoff to subsequent ticks, and reports whether this failure just
+// brought the domain's consecutive failures up to t.disableThreshold (i.e.
+// it transitioned from enabled to disabled).
+func (t *failureTracker) recordFailure(domainKey string) (justDisabled bool) {
+	state := t.stateFor(domainKey)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if state.disabled {
+		return false
+	}
+
+	state.consecutiveFailures++
+	backoff := 1 << state.consecutiveFailures
+	if backoff > maxBackoffTicks {
+		backoff = maxBackoffTicks
+	}
+	state.skipTicks = backoff
+
+	if state.consecutiveFailures >= t.disableThreshold {
+		state.disabled = true
+		return true
+	}
+	return false
+}
+
+// enable clears domainKey's disabled flag and failure state, re-enabling
+// it for the next tick. Called by Handler.EnableDomain.
+func (t *failureTracker) enable(domainKey string) {
+	state := t.stateFor(domainKey)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state.disabled = false
+	state.consecutiveFailures = 0
+	state.skipTicks = 0
+}
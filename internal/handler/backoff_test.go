@@ -0,0 +1,89 @@
+package handler
+
+import "testing"
+
+func TestFailureTrackerShouldSkipInitiallyFalse(t *testing.T) {
+	tracker := newFailureTracker(3)
+	if tracker.shouldSkip("example.com") {
+		t.Fatal("a domain with no recorded failures should not be skipped")
+	}
+}
+
+func TestFailureTrackerRecordFailureBacksOff(t *testing.T) {
+	tracker := newFailureTracker(10)
+
+	// One failure backs off for 1<<1 = 2 ticks (see recordFailure).
+	tracker.recordFailure("example.com")
+	for i := 0; i < 2; i++ {
+		if !tracker.shouldSkip("example.com") {
+			t.Fatalf("expected domain to be skipped during backoff tick %d", i+1)
+		}
+	}
+	if tracker.shouldSkip("example.com") {
+		t.Fatal("backoff should only cover a fixed number of ticks, not forever")
+	}
+}
+
+func TestFailureTrackerRecordSuccessResetsBackoff(t *testing.T) {
+	tracker := newFailureTracker(10)
+
+	tracker.recordFailure("example.com")
+	tracker.recordSuccess("example.com")
+
+	if tracker.shouldSkip("example.com") {
+		t.Fatal("a success should clear any pending backoff")
+	}
+}
+
+func TestFailureTrackerDisablesAtThreshold(t *testing.T) {
+	tracker := newFailureTracker(3)
+
+	for i := 0; i < 2; i++ {
+		if justDisabled := tracker.recordFailure("example.com"); justDisabled {
+			t.Fatalf("domain should not be disabled before reaching the threshold (failure #%d)", i+1)
+		}
+	}
+
+	if justDisabled := tracker.recordFailure("example.com"); !justDisabled {
+		t.Fatal("expected the failure crossing the threshold to report justDisabled")
+	}
+
+	if !tracker.shouldSkip("example.com") {
+		t.Fatal("a disabled domain should always be skipped")
+	}
+
+	// Further failures must not re-report justDisabled once already disabled.
+	if justDisabled := tracker.recordFailure("example.com"); justDisabled {
+		t.Fatal("an already-disabled domain should not report justDisabled again")
+	}
+}
+
+func TestFailureTrackerEnableClearsDisabled(t *testing.T) {
+	tracker := newFailureTracker(1)
+
+	tracker.recordFailure("example.com")
+	if !tracker.shouldSkip("example.com") {
+		t.Fatal("expected domain to be disabled after a single failure with threshold 1")
+	}
+
+	tracker.enable("example.com")
+	if tracker.shouldSkip("example.com") {
+		t.Fatal("enable should clear the disabled flag and pending backoff")
+	}
+}
+
+func TestNewFailureTrackerDefaultsNonPositiveThreshold(t *testing.T) {
+	tracker := newFailureTracker(0)
+	if tracker.disableThreshold != defaultDisableThreshold {
+		t.Fatalf("expected non-positive threshold to fall back to %d, got %d", defaultDisableThreshold, tracker.disableThreshold)
+	}
+}
+
+func TestFailureTrackerDomainsAreIndependent(t *testing.T) {
+	tracker := newFailureTracker(1)
+
+	tracker.recordFailure("a.example.com")
+	if tracker.shouldSkip("b.example.com") {
+		t.Fatal("a failure on one domain must not affect another domain's state")
+	}
+}
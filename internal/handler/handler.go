@@ -1,11 +1,9 @@
 package handler
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/TimothyYe/godns/internal/provider"
 
@@ -17,95 +15,69 @@ import (
 	"github.com/TimothyYe/godns/pkg/notification"
 )
 
-var (
-	errEmptyResult = errors.New("empty result")
-	errEmptyDomain = errors.New("NXDOMAIN")
-)
-
 type Handler struct {
 	Configuration       *settings.Settings
-	dnsProvider         provider.IDNSProvider
+	providers           map[string]provider.IDNSProvider
 	notificationManager notification.INotificationManager
-	cachedIP            string
-	cacheTime           time.Time
+	failures            *failureTracker
 }
 
 func (handler *Handler) SetConfiguration(conf *settings.Settings) {
 	handler.Configuration = conf
 	handler.notificationManager = notification.GetNotificationManager(handler.Configuration)
+	handler.failures = newFailureTracker(conf.DisableThreshold)
 }
 
-func (handler *Handler) SetProvider(provider provider.IDNSProvider) {
-	handler.dnsProvider = provider
+// EnableDomain clears domainName's backoff/disabled state, so it is
+// considered again on the next tick. It re-enables a single domain that was
+// auto-disabled; SetConfiguration instead resets the state of every domain.
+func (handler *Handler) EnableDomain(domainName string) {
+	handler.failures.enable(domainName)
 }
 
-func (handler *Handler) LoopUpdateIP(ctx context.Context, domains *[]settings.Domain) error {
-	ticker := time.NewTicker(time.Second * time.Duration(handler.Configuration.Interval))
-
-	// run once at the beginning
-	err := handler.UpdateIP(domains)
-	if err != nil {
-		log.WithError(err).Debug("Update IP failed during the DNS Update loop")
-	}
-	log.Debugf("DNS update loop finished, will run again in %d seconds", handler.Configuration.Interval)
-
-	for {
-		select {
-		case <-ticker.C:
-			err := handler.UpdateIP(domains)
-			if err != nil {
-				log.WithError(err).Debug("Update IP failed during the DNS Update loop")
-			}
-			log.Debugf("DNS update loop finished, will run again in %d seconds", handler.Configuration.Interval)
-		case <-ctx.Done():
-			log.Info("DNS update loop cancelled")
-			ticker.Stop()
-			return nil
-		}
+// SetProvider registers the IDNSProvider to use for domains whose
+// ProviderRef matches name. The empty name registers the default provider,
+// used by domains that don't set ProviderRef.
+func (handler *Handler) SetProvider(name string, dnsProvider provider.IDNSProvider) {
+	if handler.providers == nil {
+		handler.providers = make(map[string]provider.IDNSProvider)
 	}
+	handler.providers[name] = dnsProvider
 }
 
-func (handler *Handler) UpdateIP(domains *[]settings.Domain) error {
-	ip, err := utils.GetCurrentIP(handler.Configuration)
-	if err != nil {
-		if handler.Configuration.RunOnce {
-			return fmt.Errorf("%v: fail to get current IP", err)
-		}
-		log.Error(err)
-		return nil
-	}
-
-	if time.Now().Sub(handler.cacheTime) >= utils.DefaultIPCacheTimeout {
-		if !handler.cacheTime.IsZero() {
-			log.Debugf("cache IP (%s %s) expired", handler.cachedIP, handler.cacheTime.Format(time.DateTime))
+// providerFor returns the IDNSProvider configured for domain, resolved via
+// its ProviderRef (falling back to the default provider registered under
+// the empty name).
+func (handler *Handler) providerFor(domain *settings.Domain) (provider.IDNSProvider, error) {
+	dnsProvider, ok := handler.providers[domain.ProviderRef]
+	if !ok {
+		if domain.ProviderRef == "" {
+			return nil, fmt.Errorf("no default provider configured")
 		}
-		handler.cachedIP = ""
-		handler.cacheTime = time.Time{}
-	}
-
-	if ip == handler.cachedIP {
-		log.Debugf("IP (%s) matches cached IP (%s), skipping", ip, handler.cachedIP)
-		return nil
+		return nil, fmt.Errorf("domain %s references unknown provider %q", domain.DomainName, domain.ProviderRef)
 	}
+	return dnsProvider, nil
+}
 
-	for _, domain := range *domains {
-		err = handler.updateDNS(&domain, ip)
-		if err != nil {
-			if handler.Configuration.RunOnce {
-				return fmt.Errorf("%v: fail to update DNS", err)
-			}
-			log.Error(err)
-			return nil
-		}
+// recordFailure registers a provider/resolver failure for domainName and
+// notifies the user the first time it crosses the auto-disable threshold.
+func (handler *Handler) recordFailure(domainName string) {
+	if handler.failures.recordFailure(domainName) {
+		message := fmt.Sprintf("Domain %s has been disabled after repeated failures", domainName)
+		log.Warn(message)
+		handler.notificationManager.Send(message, "")
 	}
-	handler.cachedIP = ip
-	handler.cacheTime = time.Now()
-	log.Debugf("Cached IP address: %s", ip)
-	return nil
 }
 
 func (handler *Handler) updateDNS(domain *settings.Domain, ip string) error {
+	dnsProvider, err := handler.providerFor(domain)
+	if err != nil {
+		handler.recordFailure(domain.DomainName)
+		return err
+	}
+
 	var updatedDomains []string
+	resolveFailed := false
 	for _, subdomainName := range domain.SubDomains {
 
 		var hostname string
@@ -115,13 +87,14 @@ func (handler *Handler) updateDNS(domain *settings.Domain, ip string) error {
 			hostname = domain.DomainName
 		}
 
-		lastIP, err := utils.ResolveDNS(hostname, handler.Configuration.Resolver, handler.Configuration.IPType)
-		if err != nil && (errors.Is(err, errEmptyResult) || errors.Is(err, errEmptyDomain)) {
+		lastIP, err := utils.ResolveDNS(hostname, handler.Configuration, handler.Configuration.IPType)
+		if err != nil && (errors.Is(err, utils.ErrEmptyResult) || errors.Is(err, utils.ErrEmptyDomain)) {
 			log.Errorf("Failed to resolve DNS for domain: %s, error: %s", hostname, err)
 			continue
 		}
 		if err != nil {
 			log.Warnf("Failed to resolve DNS for domain: %s, error: %s", hostname, err)
+			resolveFailed = true
 		}
 
 		//check against the current known IP, if no change, skip update
@@ -130,7 +103,8 @@ func (handler *Handler) updateDNS(domain *settings.Domain, ip string) error {
 		} else {
 			log.Infof("IP is different from the resolved one, do update, domain: %s, current IP: %s, resolved IP: %s", hostname, ip, lastIP)
 
-			if err := handler.dnsProvider.UpdateIP(domain.DomainName, subdomainName, ip); err != nil {
+			if err := handler.applyUpdate(dnsProvider, domain, subdomainName, ip); err != nil {
+				handler.recordFailure(domain.DomainName)
 				return err
 			}
 
@@ -145,6 +119,17 @@ func (handler *Handler) updateDNS(domain *settings.Domain, ip string) error {
 		}
 	}
 
+	// Record at most one failure (or success) for the whole domain per
+	// tick, regardless of how many of its subdomains hit a resolver error,
+	// so a domain with many subdomains doesn't reach the auto-disable
+	// threshold faster than a single-subdomain one under the same
+	// per-tick failure rate.
+	if resolveFailed {
+		handler.recordFailure(domain.DomainName)
+	} else {
+		handler.failures.recordSuccess(domain.DomainName)
+	}
+
 	if len(updatedDomains) > 0 {
 		successMessage := fmt.Sprintf("[ %s ] of %s", strings.Join(updatedDomains, ", "), domain.DomainName)
 		handler.notificationManager.Send(successMessage, ip)
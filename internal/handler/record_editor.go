@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TimothyYe/godns/internal/provider"
+	"github.com/TimothyYe/godns/internal/settings"
+)
+
+// applyUpdate pushes ip for subdomainName.domain.DomainName to dnsProvider.
+// Providers that implement provider.IRecordEditor (i.e. only expose
+// create/delete rather than an atomic update) go through a list/delete/create
+// fallback; everything else keeps using the regular UpdateIP path.
+func (handler *Handler) applyUpdate(dnsProvider provider.IDNSProvider, domain *settings.Domain, subdomainName, ip string) error {
+	editor, ok := dnsProvider.(provider.IRecordEditor)
+	if !ok {
+		return dnsProvider.UpdateIP(domain.DomainName, subdomainName, ip)
+	}
+	return handler.replaceRecord(editor, domain, subdomainName, ip)
+}
+
+func (handler *Handler) replaceRecord(editor provider.IRecordEditor, domain *settings.Domain, subdomainName, ip string) error {
+	recordType := recordTypeForIPType(handler.Configuration.IPType)
+
+	records, err := editor.ListRecords(domain.DomainName, subdomainName, recordType)
+	if err != nil {
+		return fmt.Errorf("failed to list %s records for %s.%s: %w", recordType, subdomainName, domain.DomainName, err)
+	}
+
+	for _, record := range records {
+		if err := editor.DeleteRecord(domain.DomainName, subdomainName, record); err != nil {
+			return fmt.Errorf("failed to delete stale %s record for %s.%s: %w", recordType, subdomainName, domain.DomainName, err)
+		}
+	}
+
+	// Only wait when a stale record was actually deleted; a create-only
+	// tick (e.g. the subdomain's first-ever record) has nothing to
+	// propagate and shouldn't stall while holding the provider's semaphore
+	// slot.
+	if wait := handler.Configuration.RecordPropagationWait; len(records) > 0 && wait > 0 {
+		time.Sleep(time.Duration(wait) * time.Second)
+	}
+
+	// A failed create after a successful delete is deliberately surfaced as
+	// an error (rather than swallowed): updateDNS records it as a failure, so
+	// it is retried on the first tick after the resulting backoff.
+	if err := editor.CreateRecord(domain.DomainName, subdomainName, recordType, ip); err != nil {
+		return fmt.Errorf("failed to create %s record for %s.%s after deleting %d stale record(s): %w", recordType, subdomainName, domain.DomainName, len(records), err)
+	}
+	return nil
+}
+
+func recordTypeForIPType(ipType string) string {
+	if ipType == "IPv6" {
+		return "AAAA"
+	}
+	return "A"
+}
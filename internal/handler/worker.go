@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/TimothyYe/godns/internal/settings"
+	"github.com/TimothyYe/godns/internal/utils"
+)
+
+// perProviderConcurrency caps how many in-flight provider calls a single
+// provider profile allows at once, so a burst of independently-ticking
+// domains that share an account doesn't exceed the provider's rate limit.
+const perProviderConcurrency = 2
+
+// domainRuntime is the per-domain state owned exclusively by that domain's
+// worker goroutine, so it needs no locking.
+type domainRuntime struct {
+	cachedIP  string
+	cacheTime time.Time
+}
+
+// LoopUpdateIP starts one worker goroutine per domain, each with its own
+// ticker (defaulting to Configuration.Interval, overridable per domain via
+// settings.Domain.Interval), its own cached IP, and its own error/backoff
+// state. A stuck or slow provider call for one domain no longer delays the
+// update of any other domain. It blocks until ctx is cancelled.
+func (handler *Handler) LoopUpdateIP(ctx context.Context, domains *[]settings.Domain) error {
+	semaphores := handler.providerSemaphores(*domains)
+
+	var wg sync.WaitGroup
+	for i := range *domains {
+		domain := (*domains)[i]
+		sem := semaphores[domain.ProviderRef]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.runDomainLoop(ctx, &domain, sem)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// providerSemaphores builds one bounded semaphore per distinct provider
+// profile referenced by domains, shared by all of that profile's workers.
+func (handler *Handler) providerSemaphores(domains []settings.Domain) map[string]chan struct{} {
+	semaphores := make(map[string]chan struct{})
+	for _, domain := range domains {
+		if _, ok := semaphores[domain.ProviderRef]; !ok {
+			semaphores[domain.ProviderRef] = make(chan struct{}, perProviderConcurrency)
+		}
+	}
+	return semaphores
+}
+
+// runDomainLoop is the worker goroutine body for a single domain: it ticks
+// on its own interval until ctx is cancelled.
+func (handler *Handler) runDomainLoop(ctx context.Context, domain *settings.Domain, sem chan struct{}) {
+	interval := handler.Configuration.Interval
+	if domain.Interval > 0 {
+		interval = domain.Interval
+	}
+	ticker := time.NewTicker(time.Second * time.Duration(interval))
+	defer ticker.Stop()
+
+	state := &domainRuntime{}
+	tick := func() {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		if err := handler.updateDomainIP(domain, state); err != nil {
+			log.WithError(err).Debugf("Update IP failed for domain %s", domain.DomainName)
+		}
+		log.Debugf("Update loop for domain %s finished, will run again in %d seconds", domain.DomainName, interval)
+	}
+
+	// run once at the beginning
+	tick()
+
+	for {
+		select {
+		case <-ticker.C:
+			tick()
+		case <-ctx.Done():
+			log.Infof("DNS update loop for domain %s cancelled", domain.DomainName)
+			return
+		}
+	}
+}
+
+// UpdateIP runs a single, synchronous update pass over domains. It is used
+// for one-shot invocations (e.g. settings.Settings.RunOnce) where the
+// per-domain goroutines of LoopUpdateIP aren't needed.
+func (handler *Handler) UpdateIP(domains *[]settings.Domain) error {
+	for i := range *domains {
+		domain := (*domains)[i]
+		if err := handler.updateDomainIP(&domain, &domainRuntime{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (handler *Handler) updateDomainIP(domain *settings.Domain, state *domainRuntime) error {
+	if handler.failures.shouldSkip(domain.DomainName) {
+		log.Debugf("Domain %s is disabled or backing off, skipping this tick", domain.DomainName)
+		return nil
+	}
+
+	ip, err := utils.GetCurrentIP(handler.Configuration)
+	if err != nil {
+		if handler.Configuration.RunOnce {
+			return fmt.Errorf("%v: fail to get current IP", err)
+		}
+		log.Error(err)
+		return nil
+	}
+
+	if time.Now().Sub(state.cacheTime) >= utils.DefaultIPCacheTimeout {
+		if !state.cacheTime.IsZero() {
+			log.Debugf("cache IP (%s %s) expired for domain %s", state.cachedIP, state.cacheTime.Format(time.DateTime), domain.DomainName)
+		}
+		state.cachedIP = ""
+		state.cacheTime = time.Time{}
+	}
+
+	if ip == state.cachedIP {
+		log.Debugf("IP (%s) matches cached IP (%s), skipping domain %s", ip, state.cachedIP, domain.DomainName)
+		return nil
+	}
+
+	if err := handler.updateDNS(domain, ip); err != nil {
+		if handler.Configuration.RunOnce {
+			return fmt.Errorf("%v: fail to update DNS", err)
+		}
+		log.Error(err)
+		return nil
+	}
+
+	state.cachedIP = ip
+	state.cacheTime = time.Now()
+	log.Debugf("Cached IP address for domain %s: %s", domain.DomainName, ip)
+	return nil
+}
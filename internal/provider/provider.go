@@ -0,0 +1,10 @@
+package provider
+
+import "github.com/TimothyYe/godns/internal/settings"
+
+// IDNSProvider is the interface that every DNS provider implementation must
+// satisfy in order to be driven by Handler.
+type IDNSProvider interface {
+	Init(conf *settings.Settings)
+	UpdateIP(domainName, subDomainName, ip string) error
+}
@@ -0,0 +1,20 @@
+package provider
+
+// Record is a single DNS record as reported by IRecordEditor.ListRecords.
+type Record struct {
+	ID    string
+	Type  string
+	Name  string
+	Value string
+}
+
+// IRecordEditor is an optional interface a provider can implement when its
+// upstream API only exposes record create/delete rather than an in-place
+// update (e.g. a Name.com-style REST API). When a provider implements it,
+// Handler updates a record by listing the stale one, deleting it, then
+// creating the new one, instead of calling UpdateIP.
+type IRecordEditor interface {
+	ListRecords(domainName, subDomainName, recordType string) ([]Record, error)
+	CreateRecord(domainName, subDomainName, recordType, value string) error
+	DeleteRecord(domainName, subDomainName string, record Record) error
+}
@@ -0,0 +1,78 @@
+package settings
+
+// Settings holds the global godns configuration loaded from the config file.
+type Settings struct {
+	Provider    string              `json:"provider"`
+	Email       string              `json:"email"`
+	Password    string              `json:"password"`
+	LoginToken  string              `json:"login_token"`
+	Providers   map[string]Provider `json:"providers"`
+	IP          string              `json:"ip"`
+	IPUrls      []string            `json:"ip_urls"`
+	IPType      string              `json:"ip_type"`
+	IPInterface string              `json:"ip_interface"`
+	Resolver    string              `json:"resolver"`
+	// ResolverType selects how Resolver is used to resolve a domain's
+	// current record: "udp" (a plain "host:port" nameserver), "doh" (RFC
+	// 8484 DNS-over-HTTPS against an https:// Resolver), or "system" (ignore
+	// Resolver and use the OS resolver). When empty, it is inferred from
+	// Resolver: https:// means "doh", an empty Resolver means "system", and
+	// anything else means "udp". An unrecognised value means "system".
+	ResolverType string `json:"resolver_type"`
+	// ResolverBootstrapIP, when set, is used to dial the DoH endpoint
+	// directly instead of resolving its hostname, avoiding a
+	// chicken-and-egg lookup of the DoH host itself.
+	ResolverBootstrapIP string `json:"resolver_bootstrap_ip"`
+	Socks5Proxy         string `json:"socks5_proxy"`
+	// RecordPropagationWait is how long to wait, in seconds, between
+	// deleting a stale record and creating its replacement on providers
+	// that only support provider.IRecordEditor rather than an atomic
+	// UpdateIP, to give the deletion time to propagate upstream.
+	RecordPropagationWait int `json:"record_propagation_wait"`
+	// DisableThreshold is the number of consecutive provider/resolver
+	// failures at which a domain is marked disabled. Zero (the default)
+	// uses a built-in threshold.
+	DisableThreshold int      `json:"disable_threshold"`
+	Interval         int      `json:"interval"`
+	RunOnce          bool     `json:"run_once"`
+	Domains          []Domain `json:"domains"`
+	Webhook          Webhook  `json:"webhook"`
+	Notify           Notify   `json:"notify"`
+}
+
+// Provider describes a single named credential set that a Domain can
+// reference via Domain.ProviderRef. This allows one godns process to manage
+// domains spread across multiple registrars or multiple accounts at the
+// same registrar.
+type Provider struct {
+	Provider   string `json:"provider"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	LoginToken string `json:"login_token"`
+}
+
+// Domain represents a single domain and its subdomains to keep up to date.
+type Domain struct {
+	DomainName string   `json:"domain_name"`
+	SubDomains []string `json:"sub_domains"`
+	// ProviderRef names an entry in Settings.Providers to use for this
+	// domain. When empty, the top-level Provider/Email/Password/LoginToken
+	// fields are used instead, preserving single-account configurations.
+	ProviderRef string `json:"provider_ref"`
+	// Interval overrides Settings.Interval for this domain, in seconds.
+	// Zero (the default) means use the global interval.
+	Interval int `json:"interval"`
+}
+
+// Webhook holds the configuration of the optional webhook notifier.
+type Webhook struct {
+	Enabled     bool              `json:"enabled"`
+	URL         string            `json:"url"`
+	RequestBody string            `json:"request_body"`
+	Headers     map[string]string `json:"headers"`
+}
+
+// Notify holds the configuration of the optional push notifier.
+type Notify struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/TimothyYe/godns/internal/settings"
+)
+
+var (
+	// ErrEmptyResult is returned when a resolver completed successfully but
+	// returned no A/AAAA records for the queried hostname.
+	ErrEmptyResult = errors.New("empty result")
+	// ErrEmptyDomain is returned when a resolver reports NXDOMAIN for the
+	// queried hostname.
+	ErrEmptyDomain = errors.New("NXDOMAIN")
+)
+
+// ResolveDNS resolves hostname's current A/AAAA record using the resolver
+// configured by conf: the system resolver (resolver_type "system"), a
+// plain "host:port" nameserver over UDP (resolver_type "udp"), or
+// DNS-over-HTTPS (resolver_type "doh") against an https://... conf.Resolver
+// endpoint. With resolver_type unset, an https:// Resolver selects "doh", an
+// empty Resolver selects "system", and any other Resolver selects "udp"; an
+// unrecognised resolver_type selects "system".
+func ResolveDNS(hostname string, conf *settings.Settings, ipType string) (string, error) {
+	switch resolverMode(conf) {
+	case "doh":
+		return resolveDNSOverHTTPS(hostname, conf, ipType)
+	case "udp":
+		return resolveDNSUDP(hostname, conf.Resolver)
+	default:
+		return resolveDNSSystem(hostname)
+	}
+}
+
+// resolverMode decides which resolver backend ("doh", "udp", or "system")
+// ResolveDNS should use for conf, applying the resolver_type defaulting
+// rules documented on ResolveDNS.
+func resolverMode(conf *settings.Settings) string {
+	switch conf.ResolverType {
+	case "doh", "udp", "system":
+		return conf.ResolverType
+	case "":
+		// No resolver_type set: infer it from Resolver for backwards
+		// compatibility with configs predating resolver_type. A bare
+		// "host:port" nameserver keeps going through UDP (the longstanding
+		// default); only an explicit https:// endpoint or an empty
+		// Resolver falls back to something else.
+		switch {
+		case strings.HasPrefix(conf.Resolver, "https://"):
+			return "doh"
+		case conf.Resolver == "":
+			return "system"
+		default:
+			return "udp"
+		}
+	default:
+		return "system"
+	}
+}
+
+func resolveDNSSystem(hostname string) (string, error) {
+	ips, err := net.LookupHost(hostname)
+	return firstIPOrErr(ips, err)
+}
+
+func resolveDNSUDP(hostname, resolver string) (string, error) {
+	r := net.DefaultResolver
+	if resolver != "" {
+		r = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				d := net.Dialer{Timeout: 5 * time.Second}
+				return d.DialContext(ctx, "udp", resolver)
+			},
+		}
+	}
+	ips, err := r.LookupHost(context.Background(), hostname)
+	return firstIPOrErr(ips, err)
+}
+
+func firstIPOrErr(ips []string, err error) (string, error) {
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", ErrEmptyDomain
+		}
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", ErrEmptyResult
+	}
+	return ips[0], nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/TimothyYe/godns/internal/settings"
+)
+
+func TestResolverModeDefaults(t *testing.T) {
+	cases := []struct {
+		name string
+		conf *settings.Settings
+		want string
+	}{
+		{
+			name: "explicit udp",
+			conf: &settings.Settings{ResolverType: "udp", Resolver: "1.1.1.1:53"},
+			want: "udp",
+		},
+		{
+			name: "explicit doh",
+			conf: &settings.Settings{ResolverType: "doh", Resolver: "https://cloudflare-dns.com/dns-query"},
+			want: "doh",
+		},
+		{
+			name: "explicit system",
+			conf: &settings.Settings{ResolverType: "system", Resolver: "1.1.1.1:53"},
+			want: "system",
+		},
+		{
+			name: "unset type with host:port resolver stays on udp",
+			conf: &settings.Settings{Resolver: "1.1.1.1:53"},
+			want: "udp",
+		},
+		{
+			name: "unset type with https resolver infers doh",
+			conf: &settings.Settings{Resolver: "https://cloudflare-dns.com/dns-query"},
+			want: "doh",
+		},
+		{
+			name: "unset type with empty resolver falls back to system",
+			conf: &settings.Settings{},
+			want: "system",
+		},
+		{
+			name: "unknown type falls back to system",
+			conf: &settings.Settings{ResolverType: "bogus", Resolver: "1.1.1.1:53"},
+			want: "system",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolverMode(tc.conf); got != tc.want {
+				t.Errorf("resolverMode() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFirstIPOrErr(t *testing.T) {
+	if _, err := firstIPOrErr(nil, nil); err != ErrEmptyResult {
+		t.Errorf("expected ErrEmptyResult for an empty result set, got %v", err)
+	}
+
+	ip, err := firstIPOrErr([]string{"203.0.113.1", "203.0.113.2"}, nil)
+	if err != nil || ip != "203.0.113.1" {
+		t.Errorf("expected the first IP with no error, got ip=%q err=%v", ip, err)
+	}
+}
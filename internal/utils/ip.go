@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/TimothyYe/godns/internal/settings"
+)
+
+// GetCurrentIP returns the current IP address from conf.IP. conf.IPUrls and
+// conf.IPInterface are not consulted; when conf.IP is empty it returns an
+// error.
+func GetCurrentIP(conf *settings.Settings) (string, error) {
+	if conf.IP != "" {
+		return conf.IP, nil
+	}
+	return "", fmt.Errorf("no IP detection method configured")
+}
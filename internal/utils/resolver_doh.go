@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/TimothyYe/godns/internal/settings"
+)
+
+const dohRequestTimeout = 10 * time.Second
+
+// resolveDNSOverHTTPS resolves hostname via RFC 8484 DNS-over-HTTPS against
+// conf.Resolver (e.g. "https://cloudflare-dns.com/dns-query"). When
+// conf.ResolverBootstrapIP is set, it is used to dial the DoH endpoint
+// directly, avoiding a chicken-and-egg lookup of the DoH host itself.
+func resolveDNSOverHTTPS(hostname string, conf *settings.Settings, ipType string) (string, error) {
+	qType := dnsmessage.TypeA
+	if ipType == "IPv6" {
+		qType = dnsmessage.TypeAAAA
+	}
+
+	query, err := packDNSQuery(hostname, qType)
+	if err != nil {
+		return "", fmt.Errorf("failed to build DoH query: %w", err)
+	}
+
+	client := &http.Client{Timeout: dohRequestTimeout}
+	if conf.ResolverBootstrapIP != "" {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				d := net.Dialer{Timeout: dohRequestTimeout}
+				return d.DialContext(ctx, network, net.JoinHostPort(conf.ResolverBootstrapIP, port))
+			},
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, conf.Resolver, bytes.NewReader(query))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return unpackDNSAnswer(body)
+}
+
+func packDNSQuery(hostname string, qType dnsmessage.Type) ([]byte, error) {
+	name, err := dnsmessage.NewName(hostname + ".")
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{
+				Name:  name,
+				Type:  qType,
+				Class: dnsmessage.ClassINET,
+			},
+		},
+	}
+	return msg.Pack()
+}
+
+func unpackDNSAnswer(raw []byte) (string, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(raw); err != nil {
+		return "", fmt.Errorf("failed to unpack DoH response: %w", err)
+	}
+
+	if msg.Header.RCode == dnsmessage.RCodeNameError {
+		return "", ErrEmptyDomain
+	}
+
+	for _, answer := range msg.Answers {
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			return net.IP(body.A[:]).String(), nil
+		case *dnsmessage.AAAAResource:
+			return net.IP(body.AAAA[:]).String(), nil
+		}
+	}
+
+	return "", ErrEmptyResult
+}
@@ -0,0 +1,12 @@
+package utils
+
+import "time"
+
+// RootDomain is the sentinel subdomain name used to address the bare domain
+// (i.e. no subdomain prefix) in settings.Domain.SubDomains.
+const RootDomain = "@"
+
+// DefaultIPCacheTimeout is how long a domain's cached IP is trusted before
+// the handler resolves and compares the domain's DNS records again, even if
+// the current IP has not changed.
+const DefaultIPCacheTimeout = time.Hour
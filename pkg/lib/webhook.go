@@ -0,0 +1,22 @@
+package lib
+
+import "github.com/TimothyYe/godns/internal/settings"
+
+// IWebhook fires the user-configured webhook whenever a domain's IP changes.
+type IWebhook interface {
+	Execute(hostname, currentIP, lastIP string) error
+}
+
+type webhook struct {
+	conf *settings.Settings
+}
+
+func (w *webhook) Execute(hostname, currentIP, lastIP string) error {
+	return nil
+}
+
+// GetWebhook returns the webhook client for the given configuration. Its
+// Execute sends no request and always returns nil.
+func GetWebhook(conf *settings.Settings) IWebhook {
+	return &webhook{conf: conf}
+}
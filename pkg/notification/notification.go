@@ -0,0 +1,23 @@
+package notification
+
+import "github.com/TimothyYe/godns/internal/settings"
+
+// INotificationManager delivers a user-facing notification when domains are
+// updated or auto-disabled.
+type INotificationManager interface {
+	Send(title, content string)
+}
+
+type noopManager struct{}
+
+func (n *noopManager) Send(title, content string) {}
+
+// GetNotificationManager returns a no-op manager, whether or not
+// conf.Notify.Enabled is set, so callers never need to nil-check; no
+// manager that delivers notifications is implemented here.
+func GetNotificationManager(conf *settings.Settings) INotificationManager {
+	if conf == nil || !conf.Notify.Enabled {
+		return &noopManager{}
+	}
+	return &noopManager{}
+}